@@ -0,0 +1,466 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/virtuallyunknown/npminit/resolver"
+)
+
+var (
+	configFlag   = flag.String("config", "", "path to a JSON config file driving a non-interactive run")
+	nameFlag     = flag.String("name", "", "project name (enables non-interactive mode); supports name@version")
+	depsFlag     = flag.String("deps", "", "comma-separated dependencies to install, e.g. react,typescript@5.4.0")
+	templateFlag = flag.String("template", "react-spa", "template key to scaffold from")
+	noAuditFlag  = flag.Bool("no-audit", false, "skip running the package manager's audit")
+	yesFlag      = flag.Bool("yes", false, "assume yes; reserved for future scripted prompts")
+	failOnFlag   = flag.String("fail-on", "", "exit non-zero if the audit finds vulnerabilities at or above this severity: info, low, moderate, high, critical")
+)
+
+// Config drives a non-interactive run, either parsed from --config or
+// assembled from the individual flags above. NoAudit defaults false so a
+// config file that omits it still runs the audit, matching the --no-audit
+// flag's default of running it.
+type Config struct {
+	Name           string   `json:"name"`
+	Template       string   `json:"template"`
+	Dependencies   []string `json:"dependencies"`
+	PackageManager string   `json:"pm"`
+	NoAudit        bool     `json:"noAudit"`
+	FailOn         string   `json:"failOn"`
+}
+
+// nonInteractive reports whether the CLI flags ask for scripted mode instead
+// of the Bubble Tea UI.
+func nonInteractive() bool {
+	return *configFlag != "" || *nameFlag != ""
+}
+
+func loadConfig() (Config, error) {
+	if *configFlag != "" {
+		data, err := os.ReadFile(*configFlag)
+		if err != nil {
+			return Config{}, err
+		}
+
+		var cfg Config
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, err
+		}
+
+		if cfg.Name == "" {
+			return cfg, errors.New(`config file must set "name"`)
+		}
+
+		if cfg.Template == "" {
+			cfg.Template = "react-spa"
+		}
+
+		return cfg, nil
+	}
+
+	cfg := Config{
+		Name:           *nameFlag,
+		Template:       *templateFlag,
+		PackageManager: *pmFlag,
+		NoAudit:        *noAuditFlag,
+		FailOn:         *failOnFlag,
+	}
+
+	if *depsFlag != "" {
+		cfg.Dependencies = strings.Split(*depsFlag, ",")
+	}
+
+	return cfg, nil
+}
+
+// progressEvent is one line of the NDJSON progress stream scripted mode
+// writes to stdout.
+type progressEvent struct {
+	Event      string `json:"event"`
+	Dep        string `json:"dep,omitempty"`
+	Status     string `json:"status,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Warning    string `json:"warning,omitempty"`
+}
+
+func emit(event progressEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	fmt.Println(string(data))
+}
+
+// runScripted drives setup, installs, and (optionally) the audit from cfg,
+// bypassing the Bubble Tea UI, and returns the process exit code.
+func runScripted(cfg Config) int {
+	name, version := parseNameVersion(cfg.Name)
+
+	if err := validateProjectName(name); err != nil {
+		emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+		return 1
+	}
+
+	templates, err := loadTemplates()
+	if err != nil {
+		emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+		return 1
+	}
+
+	tmpl := findTemplate(templates, cfg.Template)
+	if tmpl == nil {
+		emit(progressEvent{Event: "setup", Status: "error", Error: fmt.Sprintf("unknown template %q", cfg.Template)})
+		return 1
+	}
+
+	projectPath, journal, err := getProjectPath(name)
+	if err != nil {
+		emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+		return 1
+	}
+
+	if taken, _ := projectNameTaken(name); taken {
+		emit(progressEvent{Event: "setup", Status: "warning", Warning: fmt.Sprintf("%v is already taken on the npm registry", name)})
+	}
+
+	if !journal.hasFile("package.json") {
+		if err := writeJson(projectPath, "package.json", generatePackageJSON(name, version)); err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+
+		if err := journal.recordFile("package.json"); err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+	}
+
+	if !journal.hasFile("tsconfig.json") {
+		if err := writeJson(projectPath, "tsconfig.json", generateTsconfigJSON(tmpl)); err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+
+		if err := journal.recordFile("tsconfig.json"); err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+	}
+
+	if !journal.FilesCopied {
+		written, err := copyTemplateFiles(tmpl, projectPath)
+		if err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+
+		for _, file := range written {
+			if err := journal.recordFile(file); err != nil {
+				emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+				return 1
+			}
+		}
+
+		if err := journal.recordFilesCopied(); err != nil {
+			emit(progressEvent{Event: "setup", Status: "error", Error: err.Error()})
+			return 1
+		}
+	}
+
+	emit(progressEvent{Event: "setup", Status: "ok"})
+
+	pm := scriptedPackageManager(cfg.PackageManager)
+	deps := scriptedDependencies(*tmpl, cfg.Dependencies)
+	deps = append(deps, extraDependenciesFor(deps)...)
+
+	for i := range deps {
+		if journal.hasDependency(deps[i].name) {
+			deps[i].installed = true
+		}
+	}
+
+	conflicts, err := resolveConflictsScripted(deps)
+	if err != nil {
+		// a registry/network hiccup shouldn't block setup; proceed without
+		// conflict detection rather than failing the whole run
+	} else if len(conflicts) > 0 {
+		emitConflicts(conflicts)
+		return 1
+	}
+
+	if err := installAllScripted(pm, projectPath, journal, deps); err != nil {
+		return 1
+	}
+
+	if cfg.NoAudit {
+		return 0
+	}
+
+	return runAuditScripted(pm, projectPath, cfg.FailOn)
+}
+
+func findTemplate(templates []Template, key string) *Template {
+	for i := range templates {
+		if templates[i].Key == key {
+			return &templates[i]
+		}
+	}
+
+	return nil
+}
+
+func scriptedPackageManager(name string) PackageManager {
+	switch name {
+	case "npm":
+		return npmPackageManager{}
+	case "pnpm":
+		return pnpmPackageManager{}
+	case "yarn":
+		return yarnPackageManager{}
+	case "bun":
+		return bunPackageManager{}
+	default:
+		return detectPackageManager()
+	}
+}
+
+// scriptedDependencies merges a template's declared dependencies with any
+// extra "name" / "name@version" entries passed via --deps or the config file.
+func scriptedDependencies(tmpl Template, extra []string) []Dependency {
+	deps := templateDependencies(tmpl)
+
+	for _, raw := range extra {
+		name, version := parseNameVersion(strings.TrimSpace(raw))
+
+		if name == "" {
+			continue
+		}
+
+		deps = append(deps, Dependency{name: name, version: version, selected: true})
+	}
+
+	return deps
+}
+
+// resolveConflictsScripted walks deps' transitive dependencies the same way
+// Page2's resolver does for the interactive TUI, but scripted mode has no
+// prompt to drop a dependency or override a range, so any conflict found
+// here fails the run instead of installing the conflicting ranges silently.
+func resolveConflictsScripted(deps []Dependency) ([]resolver.Conflict, error) {
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		names = append(names, dep.name)
+	}
+
+	res := resolver.New(resolverFetch)
+
+	return res.Resolve(names, nil)
+}
+
+// emitConflicts reports a failed conflict check as a single NDJSON line so
+// scripted/CI consumers get the same requester/range detail Page2 shows.
+func emitConflicts(conflicts []resolver.Conflict) {
+	data, err := json.Marshal(conflicts)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf(`{"event":"resolve","status":"error","conflicts":%s}`+"\n", data)
+}
+
+// installAllScripted resolves every dep's exact "name@version" through a
+// bounded worker pool (read-only registry lookups with no shared state to
+// race on), then installs the resolved prod deps and dev deps as one
+// package manager call each, same as Page3's grouped installs, since both
+// groups mutate the shared package.json/lockfile and can't install
+// concurrently. It emits an NDJSON "install" event per dependency as its
+// group finishes and records each success in journal so a re-run can skip
+// it.
+func installAllScripted(pm PackageManager, projectPath string, journal *Journal, deps []Dependency) error {
+	concurrency := resolveConcurrency(deps)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	targets := make([]string, len(deps))
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				dep := deps[i]
+
+				target := dep.name
+				if pkg, err := fetchPackageInfo(dep.name); err == nil {
+					if version, err := resolveVersion(pkg, dep.version); err == nil {
+						target = fmt.Sprintf("%v@%v", dep.name, version)
+					}
+				}
+
+				targets[i] = target
+			}
+		}()
+	}
+
+	for i, dep := range deps {
+		if dep.installed {
+			continue
+		}
+
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	var prodGroup, devGroup []int
+
+	for i, dep := range deps {
+		if dep.installed {
+			continue
+		}
+
+		if dep.devDependency {
+			devGroup = append(devGroup, i)
+		} else {
+			prodGroup = append(prodGroup, i)
+		}
+	}
+
+	// Both groups are attempted even if the first fails, so one group's
+	// failure doesn't stop the other's deps from installing and being
+	// journaled; the first group's error is the one returned.
+	prodErr := installGroupScripted(pm, projectPath, journal, deps, targets, prodGroup, false)
+	devErr := installGroupScripted(pm, projectPath, journal, deps, targets, devGroup, true)
+
+	if prodErr != nil {
+		return prodErr
+	}
+
+	return devErr
+}
+
+// installGroupScripted installs every dep in indices through a single
+// package manager call, emitting the same NDJSON "install" event per
+// dependency installAllScripted always has, with the whole group's call
+// duration attributed to each one. If the call itself fails, every dep in
+// the group is reported as errored; if it succeeds, each dep is still
+// journaled and reported individually so one journal write failing doesn't
+// stop the rest of an already-installed group from being recorded.
+func installGroupScripted(pm PackageManager, projectPath string, journal *Journal, deps []Dependency, targets []string, indices []int, dev bool) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	group := make([]string, 0, len(indices))
+	for _, i := range indices {
+		group = append(group, targets[i])
+	}
+
+	start := time.Now()
+	_, execErr := execOutput(pm.Install(group, dev), projectPath)
+	duration := time.Since(start).Milliseconds()
+
+	if execErr.error != nil {
+		for _, i := range indices {
+			emit(progressEvent{Event: "install", Dep: deps[i].name, Status: "error", DurationMs: duration, Error: execErr.error.Error()})
+		}
+
+		return execErr.error
+	}
+
+	var firstErr error
+
+	for _, i := range indices {
+		if err := journal.recordDependency(deps[i].name); err != nil {
+			emit(progressEvent{Event: "install", Dep: deps[i].name, Status: "error", DurationMs: duration, Error: err.Error()})
+
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		emit(progressEvent{Event: "install", Dep: deps[i].name, Status: "ok", DurationMs: duration})
+	}
+
+	return firstErr
+}
+
+func runAuditScripted(pm PackageManager, projectPath string, failOn string) int {
+	args := pm.Audit()
+	data, execErr := execOutput(args, projectPath)
+
+	report := data
+	if execErr.error != nil {
+		report = execErr.stdout
+	}
+
+	audit, parseErr := pm.ParseAudit([]byte(report))
+	if parseErr != nil {
+		emit(progressEvent{Event: "audit", Status: "error", Error: parseErr.Error()})
+		return 1
+	}
+
+	auditJSON, err := json.Marshal(audit)
+	if err != nil {
+		emit(progressEvent{Event: "audit", Status: "error", Error: err.Error()})
+		return 1
+	}
+
+	fmt.Printf(`{"event":"audit","status":"ok","report":%s}`+"\n", auditJSON)
+
+	if severityAtOrAbove(audit, failOn) {
+		return 1
+	}
+
+	return 0
+}
+
+var severityRank = map[string]int{"info": 0, "low": 1, "moderate": 2, "high": 3, "critical": 4}
+
+// severityAtOrAbove reports whether audit found any vulnerability at or
+// above failOn's severity. An empty or unrecognized failOn never fails.
+func severityAtOrAbove(audit npmAuditJSON, failOn string) bool {
+	threshold, ok := severityRank[strings.ToLower(failOn)]
+	if !ok {
+		return false
+	}
+
+	v := audit.Metadata.Vulnerabilities
+	counts := []struct {
+		rank  int
+		count int
+	}{
+		{severityRank["info"], v.Info},
+		{severityRank["low"], v.Low},
+		{severityRank["moderate"], v.Moderate},
+		{severityRank["high"], v.High},
+		{severityRank["critical"], v.Critical},
+	}
+
+	for _, c := range counts {
+		if c.rank >= threshold && c.count > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+)
+
+const journalFileName = ".npminit-state.json"
+
+// Journal records each step npminit has completed for a project — created
+// directories, written files, installed dependencies — so a run that fails
+// partway through can be rolled back or resumed instead of leaving a
+// half-populated directory behind.
+type Journal struct {
+	mu sync.Mutex
+
+	ProjectPath   string   `json:"projectPath"`
+	DirsCreated   []string `json:"dirsCreated"`
+	FilesWritten  []string `json:"filesWritten"`
+	FilesCopied   bool     `json:"filesCopied"`
+	DepsInstalled []string `json:"depsInstalled"`
+}
+
+func journalPath(projectPath string) string {
+	return path.Join(projectPath, journalFileName)
+}
+
+// loadJournal reads the journal from projectPath, if one exists there.
+func loadJournal(projectPath string) (*Journal, error) {
+	data, err := os.ReadFile(journalPath(projectPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var journal Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, err
+	}
+
+	journal.ProjectPath = projectPath
+
+	return &journal, nil
+}
+
+func (j *Journal) saveLocked() error {
+	data, err := json.MarshalIndent(j, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(journalPath(j.ProjectPath), data, 0644)
+}
+
+func (j *Journal) recordDir(dir string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.DirsCreated = append(j.DirsCreated, dir)
+
+	return j.saveLocked()
+}
+
+func (j *Journal) recordFile(file string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.FilesWritten = append(j.FilesWritten, file)
+
+	return j.saveLocked()
+}
+
+func (j *Journal) recordFilesCopied() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.FilesCopied = true
+
+	return j.saveLocked()
+}
+
+func (j *Journal) recordDependency(name string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, dep := range j.DepsInstalled {
+		if dep == name {
+			return nil
+		}
+	}
+
+	j.DepsInstalled = append(j.DepsInstalled, name)
+
+	return j.saveLocked()
+}
+
+func (j *Journal) hasFile(file string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, f := range j.FilesWritten {
+		if f == file {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (j *Journal) hasDependency(name string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, dep := range j.DepsInstalled {
+		if dep == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lockFiles are the lockfiles any of the supported package managers might
+// have written; rollback removes whichever is present.
+var lockFiles = []string{"package-lock.json", "pnpm-lock.yaml", "yarn.lock", "bun.lockb"}
+
+// rollback undoes only what the journal recorded: the files npminit wrote
+// (including copied template files), node_modules and its lockfile if any
+// dependency was installed, and the directories npminit created, innermost
+// first, left alone if they still hold something rollback didn't recognize
+// (most likely files the user added while resuming). Beyond those, it only
+// removes directories left empty by these removals, and ProjectPath itself
+// once nothing is left in it.
+func (j *Journal) rollback() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, file := range j.FilesWritten {
+		full := path.Join(j.ProjectPath, file)
+
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		removeEmptyParents(j.ProjectPath, path.Dir(full))
+	}
+
+	if len(j.DepsInstalled) > 0 {
+		if err := os.RemoveAll(path.Join(j.ProjectPath, "node_modules")); err != nil {
+			return err
+		}
+
+		for _, lockFile := range lockFiles {
+			if err := os.Remove(path.Join(j.ProjectPath, lockFile)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	for i := len(j.DirsCreated) - 1; i >= 0; i-- {
+		// best-effort: os.Remove fails (and is ignored) if the directory
+		// still holds something rollback didn't recognize.
+		_ = os.Remove(path.Join(j.ProjectPath, j.DirsCreated[i]))
+	}
+
+	if err := os.Remove(journalPath(j.ProjectPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if entries, err := os.ReadDir(j.ProjectPath); err == nil && len(entries) == 0 {
+		return os.Remove(j.ProjectPath)
+	}
+
+	return nil
+}
+
+// removeEmptyParents removes dir and each ancestor below root as long as
+// each is now empty, stopping at the first non-empty (or missing) one.
+func removeEmptyParents(root, dir string) {
+	for dir != root && dir != "." && dir != string(os.PathSeparator) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+
+		dir = path.Dir(dir)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestJournalRecordDependencyDedupes(t *testing.T) {
+	journal := &Journal{ProjectPath: t.TempDir()}
+
+	if err := journal.recordDependency("react"); err != nil {
+		t.Fatalf("recordDependency: %v", err)
+	}
+
+	if err := journal.recordDependency("react"); err != nil {
+		t.Fatalf("recordDependency (again): %v", err)
+	}
+
+	if !journal.hasDependency("react") {
+		t.Error("hasDependency(react) = false, want true")
+	}
+
+	if len(journal.DepsInstalled) != 1 {
+		t.Fatalf("DepsInstalled = %v, want a single entry", journal.DepsInstalled)
+	}
+}
+
+func TestJournalRoundTripsThroughLoadJournal(t *testing.T) {
+	dir := t.TempDir()
+	journal := &Journal{ProjectPath: dir}
+
+	if err := journal.recordDir("src"); err != nil {
+		t.Fatalf("recordDir: %v", err)
+	}
+
+	if err := journal.recordFile("package.json"); err != nil {
+		t.Fatalf("recordFile: %v", err)
+	}
+
+	if err := journal.recordDependency("react"); err != nil {
+		t.Fatalf("recordDependency: %v", err)
+	}
+
+	loaded, err := loadJournal(dir)
+	if err != nil {
+		t.Fatalf("loadJournal: %v", err)
+	}
+
+	if !loaded.hasFile("package.json") || !loaded.hasDependency("react") {
+		t.Fatalf("loadJournal(%v) = %+v, missing recorded state", dir, loaded)
+	}
+
+	if len(loaded.DirsCreated) != 1 || loaded.DirsCreated[0] != "src" {
+		t.Fatalf("DirsCreated = %v, want [src]", loaded.DirsCreated)
+	}
+}
+
+func TestJournalRollbackRemovesOnlyWhatItWrote(t *testing.T) {
+	dir := t.TempDir()
+	journal := &Journal{ProjectPath: dir}
+
+	if err := os.MkdirAll(path.Join(dir, "src"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	if err := journal.recordDir("src"); err != nil {
+		t.Fatalf("recordDir: %v", err)
+	}
+
+	if err := os.WriteFile(path.Join(dir, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := journal.recordFile("package.json"); err != nil {
+		t.Fatalf("recordFile: %v", err)
+	}
+
+	// A file the journal never recorded; rollback must leave it alone and,
+	// as a result, leave the directory it lives in behind too.
+	if err := os.WriteFile(path.Join(dir, "notes.txt"), []byte("keep me"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := journal.rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(dir, "package.json")); !os.IsNotExist(err) {
+		t.Errorf("package.json still exists after rollback (err=%v)", err)
+	}
+
+	if _, err := os.Stat(path.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("notes.txt should survive rollback, stat err=%v", err)
+	}
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		t.Error("project directory was removed even though an untracked file remained")
+	}
+}
+
+func TestJournalRollbackRemovesLockfileAndNodeModulesWhenDepsInstalled(t *testing.T) {
+	dir := t.TempDir()
+	journal := &Journal{ProjectPath: dir}
+
+	if err := os.MkdirAll(path.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	if err := os.WriteFile(path.Join(dir, "package-lock.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := journal.recordDependency("react"); err != nil {
+		t.Fatalf("recordDependency: %v", err)
+	}
+
+	if err := journal.rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	if _, err := os.Stat(path.Join(dir, "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("node_modules still exists after rollback (err=%v)", err)
+	}
+
+	if _, err := os.Stat(path.Join(dir, "package-lock.json")); !os.IsNotExist(err) {
+		t.Errorf("package-lock.json still exists after rollback (err=%v)", err)
+	}
+}
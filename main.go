@@ -1,8 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -10,15 +14,65 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/virtuallyunknown/npminit/resolver"
 )
 
-type SetupMessage struct{ projectPath string }
+// lookupConcurrencyFlag caps how many dependency versions get resolved
+// against the registry in parallel before installing. It does not apply to
+// installing: the installs themselves run as two grouped package manager
+// calls (prod deps, then dev deps), never concurrently, since they mutate
+// the shared package.json/lockfile. 0 means "auto": min(NumCPU, number of
+// selected dependencies).
+var lookupConcurrencyFlag = flag.Int("lookup-concurrency", 0, "max parallel registry version lookups before installing, does not affect install parallelism (default: min(NumCPU, selected dependencies))")
+
+// pmFlag overrides auto-detection of the package manager.
+var pmFlag = flag.String("pm", "", "package manager to use: npm, pnpm, yarn, or bun (default: auto-detect from PATH)")
+
+// detectPackageManager honors --pm if set, otherwise prefers whichever of
+// pnpm, yarn, or bun is first found on PATH, falling back to npm.
+func detectPackageManager() PackageManager {
+	switch *pmFlag {
+	case "npm":
+		return npmPackageManager{}
+	case "pnpm":
+		return pnpmPackageManager{}
+	case "yarn":
+		return yarnPackageManager{}
+	case "bun":
+		return bunPackageManager{}
+	}
+
+	candidates := []struct {
+		name string
+		pm   PackageManager
+	}{
+		{"pnpm", pnpmPackageManager{}},
+		{"yarn", yarnPackageManager{}},
+		{"bun", bunPackageManager{}},
+	}
+
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate.name); err == nil {
+			return candidate.pm
+		}
+	}
+
+	return npmPackageManager{}
+}
+
+type SetupMessage struct {
+	projectPath string
+	taken       bool
+	journal     *Journal
+}
 type ExtraDepsMessage struct {
 	dependencies []Dependency
 }
+type TargetsResolvedMsg struct{ targets map[int]string }
 type InstallAllMsg struct{}
-type OnInstalledMsg struct{ index int }
+type OnBatchInstalledMsg struct{ indices []int }
 type AuditMsg struct{ audit npmAuditJSON }
+type ResolveMsg struct{ conflicts []resolver.Conflict }
 type ErrorMsg struct{ error error }
 type ExecError struct {
 	stderr string
@@ -29,8 +83,10 @@ type ExecError struct {
 type PageNumber int
 
 const (
-	Page1View PageNumber = iota
+	Page0View PageNumber = iota
+	Page1View
 	Page2View
+	PageConflictView
 	Page3View
 	Page4View
 	Page5View
@@ -38,23 +94,38 @@ const (
 
 type Dependency struct {
 	name          string
+	version       string
+	target        string
 	selected      bool
 	devDependency bool
 	installing    bool
 	installed     bool
+	startedAt     time.Time
 }
 
 type Model struct {
-	view         PageNumber
-	dependencies []Dependency
-	audit        npmAuditJSON
-	projectPath  string
-	installCount int
-	cursor       int
-	error        string
-	textinput    textinput.Model
-	spinner      spinner.Model
-	stopwatch    stopwatch.Model
+	view              PageNumber
+	packageManager    PackageManager
+	templates         []Template
+	template          *Template
+	templateCursor    int
+	dependencies      []Dependency
+	conflicts         []resolver.Conflict
+	overrides         map[string]string
+	overriding        bool
+	journal           *Journal
+	audit             npmAuditJSON
+	projectPath       string
+	projectName       string
+	projectVersion    string
+	installCount      int
+	lookupConcurrency int
+	cursor            int
+	error             string
+	warning           string
+	textinput         textinput.Model
+	spinner           spinner.Model
+	stopwatch         stopwatch.Model
 }
 
 func (m Model) Init() tea.Cmd {
@@ -64,12 +135,76 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.error != "" {
+			if msg.String() == "r" && m.journal != nil {
+				_ = m.journal.rollback()
+			}
+
+			return m, tea.Quit
+		}
+
+		if m.overriding {
+			if msg.Type == tea.KeyEsc {
+				m.overriding = false
+				m.textinput.SetValue("")
+				m.textinput.Blur()
+				return m, nil
+			}
+
+			if msg.Type == tea.KeyEnter {
+				if value := strings.TrimSpace(m.textinput.Value()); value != "" {
+					if m.overrides == nil {
+						m.overrides = map[string]string{}
+					}
+
+					m.overrides[m.conflicts[m.cursor].Name] = value
+				}
+
+				m.overriding = false
+				m.textinput.SetValue("")
+				m.textinput.Blur()
+
+				return m, func() tea.Msg { return resolveDependencies(&m) }
+			}
+
+			var cmd tea.Cmd
+			m.textinput, cmd = m.textinput.Update(msg)
+
+			return m, cmd
+		}
+
 		if msg.Type == tea.KeyEsc || msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyCtrlD {
 			return m, tea.Quit
 		}
 
+		if m.view == Page0View {
+			if msg.Type == tea.KeyUp {
+				if m.templateCursor > 0 {
+					m.templateCursor--
+				}
+				return m, nil
+			}
+
+			if msg.Type == tea.KeyDown {
+				if m.templateCursor < len(m.templates)-1 {
+					m.templateCursor++
+				}
+				return m, nil
+			}
+
+			if msg.Type == tea.KeyEnter {
+				tmpl := m.templates[m.templateCursor]
+				m.template = &tmpl
+				m.dependencies = templateDependencies(tmpl)
+				m.view = Page1View
+				return m, nil
+			}
+		}
+
 		if m.view == Page1View {
 			if msg.Type == tea.KeyEnter {
+				m.projectName, m.projectVersion = parseNameVersion(m.textinput.Value())
+
 				return m, func() tea.Msg { return setupProject(&m) }
 			}
 
@@ -104,41 +239,154 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			if msg.Type == tea.KeyEnter {
-				m.view = Page3View
-				return m, func() tea.Msg { return extraDependencies(&m) }
+				return m, func() tea.Msg { return resolveDependencies(&m) }
+			}
+		}
+
+		if m.view == PageConflictView {
+			if msg.Type == tea.KeyUp {
+				if m.cursor > 0 {
+					m.cursor--
+				}
+				return m, nil
+			}
+
+			if msg.Type == tea.KeyDown {
+				if m.cursor < len(m.conflicts)-1 {
+					m.cursor++
+				}
+				return m, nil
+			}
+
+			if msg.String() == "d" {
+				owners := m.conflicts[m.cursor].Owners
+
+				for i := range m.dependencies {
+					for _, owner := range owners {
+						if m.dependencies[i].name == owner {
+							m.dependencies[i].selected = false
+						}
+					}
+				}
+
+				return m, func() tea.Msg { return resolveDependencies(&m) }
+			}
+
+			if msg.String() == "o" {
+				m.overriding = true
+				m.textinput.Placeholder = "semver range, e.g. ^2.0.0"
+				m.textinput.SetValue("")
+				m.textinput.Focus()
+
+				return m, nil
+			}
+
+			if msg.Type == tea.KeyEnter {
+				return m, func() tea.Msg { return resolveDependencies(&m) }
 			}
 		}
 
 	case SetupMessage:
 		m.projectPath = msg.projectPath
+		m.journal = msg.journal
 		m.view = Page2View
+
+		if msg.taken {
+			m.warning = fmt.Sprintf("%v is already taken on the npm registry", m.projectName)
+		}
+
 		return m, nil
 
+	case ResolveMsg:
+		m.conflicts = msg.conflicts
+
+		if len(m.conflicts) > 0 {
+			m.cursor = 0
+			m.view = PageConflictView
+			return m, nil
+		}
+
+		m.view = Page3View
+		return m, func() tea.Msg { return extraDependencies(&m) }
+
 	case ExtraDepsMessage:
 		m.dependencies = append(m.dependencies, msg.dependencies...)
+
+		if m.journal != nil {
+			for i := range m.dependencies {
+				if m.journal.hasDependency(m.dependencies[i].name) {
+					m.dependencies[i].installed = true
+				}
+			}
+		}
+
+		m.lookupConcurrency = resolveConcurrency(m.dependencies)
+
 		cmds := []tea.Cmd{
 			m.stopwatch.Start(),
-			func() tea.Msg { return InstallAllMsg{} },
+			func() tea.Msg { return resolveInstallTargets(&m) },
 		}
 
 		return m, tea.Sequence(cmds...)
 
+	case TargetsResolvedMsg:
+		for i, target := range msg.targets {
+			m.dependencies[i].target = target
+		}
+
+		return m, func() tea.Msg { return InstallAllMsg{} }
+
 	case InstallAllMsg:
+		// One group of dependencies installs through a single package
+		// manager call at a time, so package.json/the lockfile only ever
+		// see one writer, but every dependency in that group shows as
+		// installing (and its own spinner row) for the whole call instead
+		// of one dependency at a time.
 		for i := range m.dependencies {
-			if m.dependencies[i].selected && !m.dependencies[i].installed && !m.dependencies[i].installing {
-				m.dependencies[i].installing = true
+			if m.dependencies[i].installing {
+				return m, nil
+			}
+		}
+
+		var group []int
+		dev := false
 
-				return m, func() tea.Msg { return installDependency(&m, i) }
+		for i := range m.dependencies {
+			if m.dependencies[i].selected && !m.dependencies[i].installed && !m.dependencies[i].devDependency {
+				group = append(group, i)
 			}
 		}
 
-		m.view = Page4View
-		return m, func() tea.Msg { return runAudit(&m) }
+		if len(group) == 0 {
+			dev = true
 
-	case OnInstalledMsg:
-		m.dependencies[msg.index].installing = false
-		m.dependencies[msg.index].installed = true
-		m.installCount++
+			for i := range m.dependencies {
+				if m.dependencies[i].selected && !m.dependencies[i].installed && m.dependencies[i].devDependency {
+					group = append(group, i)
+				}
+			}
+		}
+
+		if len(group) == 0 {
+			m.view = Page4View
+			return m, func() tea.Msg { return runAudit(&m) }
+		}
+
+		now := time.Now()
+		for _, i := range group {
+			m.dependencies[i].installing = true
+			m.dependencies[i].startedAt = now
+		}
+
+		return m, func() tea.Msg { return installBatch(&m, group, dev) }
+
+	case OnBatchInstalledMsg:
+		for _, i := range msg.indices {
+			m.dependencies[i].installing = false
+			m.dependencies[i].installed = true
+		}
+
+		m.installCount += len(msg.indices)
 
 		return m, func() tea.Msg { return InstallAllMsg{} }
 
@@ -169,15 +417,39 @@ func (m Model) View() string {
 
 	if m.error != "" {
 		view = fmt.Sprintf(" %v %v\n%v\n", elm.cross, style.error.Render("There was an error"), m.error)
+
+		if m.journal != nil {
+			view += fmt.Sprintf("\n %v\n", style.textGray.Render("Press 'r' to delete the partial project, or any other key to keep it and resume by re-running npminit in the same directory."))
+		}
+
 		return view
 	}
 
+	if m.view == Page0View {
+		view = fmt.Sprintf(" %v Select a project template:\n\n", elm.question)
+
+		for i, tmpl := range m.templates {
+			label := fmt.Sprintf("%v - %v", tmpl.Name, tmpl.Description)
+
+			if m.templateCursor == i {
+				view += fmt.Sprintf(" ❯ %v\n", label)
+			} else {
+				view += fmt.Sprintf("   %v\n", style.textGray.Render(label))
+			}
+		}
+	}
+
 	if m.view == Page1View {
 		view = fmt.Sprintf(" %v Enter a name for your project: %v", style.textBlue.Render("?"), m.textinput.View())
 	}
 
 	if m.view == Page2View {
-		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.textinput.Value())
+		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.projectName)
+
+		if m.warning != "" {
+			view += fmt.Sprintf(" %v %v\n", elm.question, style.textGray.Render(m.warning))
+		}
+
 		view += fmt.Sprintf(" %v Select dependencies to install:\n\n", elm.question)
 
 		for i := 0; i < len(m.dependencies); i++ {
@@ -197,21 +469,54 @@ func (m Model) View() string {
 		}
 	}
 
+	if m.view == PageConflictView {
+		view = fmt.Sprintf(" %v Dependency conflicts found:\n\n", elm.cross)
+
+		for i, conflict := range m.conflicts {
+			if m.cursor == i {
+				view += fmt.Sprintf(" ❯ %v\n", conflict.Name)
+			} else {
+				view += fmt.Sprintf("   %v\n", conflict.Name)
+			}
+
+			for _, constraint := range conflict.Constraints {
+				view += fmt.Sprintf("     %v → %v\n", constraint.Requester, constraint.Range)
+			}
+		}
+
+		if m.overriding {
+			view += fmt.Sprintf("\n %v Override the range for %v: %v", style.textBlue.Render("?"), m.conflicts[m.cursor].Name, m.textinput.View())
+		} else {
+			view += fmt.Sprintf("\n %v\n", style.textGray.Render("Press 'd' to drop the dependency that pulled this in, 'o' to override its range, or Enter to re-check."))
+		}
+	}
+
 	if m.view == Page3View {
-		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.textinput.Value())
+		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.projectName)
 		view += fmt.Sprintf(" %v Installing dependencies... \n", elm.check)
 
-		for i, dep := range m.dependencies {
-			if m.dependencies[i].installing {
-				view += fmt.Sprintf(" %v Installing: %v (%v)\n", m.spinner.View(), dep.name, m.stopwatch.View())
+		var queued []string
+
+		for _, dep := range m.dependencies {
+			if dep.installing {
+				elapsed := time.Since(dep.startedAt).Round(time.Millisecond)
+				view += fmt.Sprintf(" %v Installing: %v (%v)\n", m.spinner.View(), dep.name, elapsed)
+			} else if dep.selected && !dep.installed {
+				queued = append(queued, dep.name)
 			}
 		}
+
+		// Only one group installs at a time, so everything else selected is
+		// just waiting for its group's turn, not installing in parallel.
+		if len(queued) > 0 {
+			view += fmt.Sprintf(" %v\n", style.textGray.Render(fmt.Sprintf("Queued: %v", strings.Join(queued, ", "))))
+		}
 	}
 
 	if m.view == Page4View {
-		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.textinput.Value())
+		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.projectName)
 		view += fmt.Sprintf(" %v Installed %v dependencies.\n", elm.check, m.installCount)
-		view += fmt.Sprintf(" %v Running npm audit.\n", m.spinner.View())
+		view += fmt.Sprintf(" %v Running %v audit.\n", m.spinner.View(), m.packageManager.Name())
 	}
 
 	if m.view == Page5View {
@@ -219,12 +524,12 @@ func (m Model) View() string {
 		severityStatus := severityStatus(&m.audit)
 
 		if m.audit.Metadata.Vulnerabilities.Total > 0 {
-			statusText = fmt.Sprintf("Found %v vulenrabilities. Run \"npm audit\" to fix.", m.audit.Metadata.Vulnerabilities.Total)
+			statusText = fmt.Sprintf("Found %v vulenrabilities. Run %q to fix.", m.audit.Metadata.Vulnerabilities.Total, m.packageManager.Name()+" audit")
 		} else {
-			statusText = "Npm audit found no vulenrabilities"
+			statusText = fmt.Sprintf("%v audit found no vulenrabilities", m.packageManager.Name())
 		}
 
-		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.textinput.Value())
+		view = fmt.Sprintf(" %v Project name: %v\n", elm.check, m.projectName)
 		view += fmt.Sprintf(" %v Installed %v dependencies.\n", elm.check, m.installCount)
 		view += fmt.Sprintf(" %v %v%v\n", elm.check, severityStatus, statusText)
 		view += fmt.Sprintf(" %v %v Project setup complete in %v\n", elm.check, style.success.Render("Success"), m.stopwatch.Elapsed())
@@ -249,23 +554,57 @@ func initialModel() Model {
 
 	watch := stopwatch.NewWithInterval(time.Millisecond)
 
+	templates, err := loadTemplates()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	return Model{
-		spinner:   loader,
-		stopwatch: watch,
-		textinput: ti,
-		dependencies: []Dependency{
-			{name: "typescript", selected: true, devDependency: true},
-			{name: "react", selected: true, devDependency: false},
-			{name: "kysely", selected: true, devDependency: false},
-			{name: "esbuild", selected: true, devDependency: true},
-			{name: "tailwindcss", selected: true, devDependency: true},
-			{name: "nodemon", selected: true, devDependency: true},
-			{name: "dotenv", selected: true, devDependency: true},
-		},
+		view:           Page0View,
+		packageManager: detectPackageManager(),
+		spinner:        loader,
+		stopwatch:      watch,
+		textinput:      ti,
+		templates:      templates,
 	}
 }
 
+// resolveConcurrency returns the configured --lookup-concurrency, or
+// min(NumCPU, selected dependencies) when it wasn't set. It only bounds how
+// many version lookups run in parallel, never how many installs do.
+func resolveConcurrency(dependencies []Dependency) int {
+	if *lookupConcurrencyFlag > 0 {
+		return *lookupConcurrencyFlag
+	}
+
+	selected := 0
+	for _, dep := range dependencies {
+		if dep.selected {
+			selected++
+		}
+	}
+
+	if concurrency := runtime.NumCPU(); concurrency < selected {
+		return concurrency
+	}
+
+	return selected
+}
+
 func main() {
+	flag.Parse()
+
+	if nonInteractive() {
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		os.Exit(runScripted(cfg))
+	}
+
 	model := initialModel()
 	program := tea.NewProgram(model)
 
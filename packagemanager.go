@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// PackageManager abstracts the shell commands npminit needs from the
+// underlying Node package manager, so installBatch and runAudit don't have
+// to special-case npm, pnpm, yarn, or bun. Install takes every dep in one
+// call so a group installs through a single process/writer instead of one
+// per dependency.
+type PackageManager interface {
+	Name() string
+	Install(deps []string, dev bool) []string
+	Audit() []string
+	ParseAudit(data []byte) (npmAuditJSON, error)
+}
+
+type npmPackageManager struct{}
+
+func (npmPackageManager) Name() string { return "npm" }
+
+func (npmPackageManager) Install(deps []string, dev bool) []string {
+	args := []string{"npm", "install"}
+
+	if dev {
+		args = append(args, "-D")
+	}
+
+	return append(append(args, deps...), "--color=always")
+}
+
+func (npmPackageManager) Audit() []string {
+	return []string{"npm", "audit", "--json"}
+}
+
+func (npmPackageManager) ParseAudit(data []byte) (npmAuditJSON, error) {
+	var audit npmAuditJSON
+	err := json.Unmarshal(data, &audit)
+	return audit, err
+}
+
+type pnpmPackageManager struct{}
+
+func (pnpmPackageManager) Name() string { return "pnpm" }
+
+func (pnpmPackageManager) Install(deps []string, dev bool) []string {
+	args := []string{"pnpm", "add"}
+
+	if dev {
+		args = append(args, "-D")
+	}
+
+	return append(args, deps...)
+}
+
+func (pnpmPackageManager) Audit() []string {
+	return []string{"pnpm", "audit", "--json"}
+}
+
+func (pnpmPackageManager) ParseAudit(data []byte) (npmAuditJSON, error) {
+	// pnpm's audit payload is shaped like npm's.
+	var audit npmAuditJSON
+	err := json.Unmarshal(data, &audit)
+	return audit, err
+}
+
+type yarnPackageManager struct{}
+
+func (yarnPackageManager) Name() string { return "yarn" }
+
+func (yarnPackageManager) Install(deps []string, dev bool) []string {
+	args := []string{"yarn", "add"}
+
+	if dev {
+		args = append(args, "-D")
+	}
+
+	return append(args, deps...)
+}
+
+func (yarnPackageManager) Audit() []string {
+	return []string{"yarn", "audit", "--json"}
+}
+
+func (yarnPackageManager) ParseAudit(data []byte) (npmAuditJSON, error) {
+	var audit npmAuditJSON
+
+	// yarn classic emits one JSON object per line; the "auditSummary" line
+	// carries the totals npmAuditJSON needs.
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var event struct {
+			Type string `json:"type"`
+			Data struct {
+				Vulnerabilities struct {
+					Info     int `json:"info"`
+					Low      int `json:"low"`
+					Moderate int `json:"moderate"`
+					High     int `json:"high"`
+					Critical int `json:"critical"`
+				} `json:"vulnerabilities"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal(line, &event); err != nil || event.Type != "auditSummary" {
+			continue
+		}
+
+		v := event.Data.Vulnerabilities
+		audit.Metadata.Vulnerabilities = struct {
+			Info     int `json:"info"`
+			Low      int `json:"low"`
+			Moderate int `json:"moderate"`
+			High     int `json:"high"`
+			Critical int `json:"critical"`
+			Total    int `json:"total"`
+		}{
+			Info:     v.Info,
+			Low:      v.Low,
+			Moderate: v.Moderate,
+			High:     v.High,
+			Critical: v.Critical,
+			Total:    v.Info + v.Low + v.Moderate + v.High + v.Critical,
+		}
+
+		return audit, nil
+	}
+
+	return audit, nil
+}
+
+type bunPackageManager struct{}
+
+func (bunPackageManager) Name() string { return "bun" }
+
+func (bunPackageManager) Install(deps []string, dev bool) []string {
+	args := []string{"bun", "add"}
+
+	if dev {
+		args = append(args, "-d")
+	}
+
+	return append(args, deps...)
+}
+
+func (bunPackageManager) Audit() []string {
+	return []string{"bun", "audit", "--json"}
+}
+
+func (bunPackageManager) ParseAudit(data []byte) (npmAuditJSON, error) {
+	var audit npmAuditJSON
+	err := json.Unmarshal(data, &audit)
+	return audit, err
+}
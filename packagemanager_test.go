@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNpmInstallArgs(t *testing.T) {
+	got := npmPackageManager{}.Install([]string{"react", "react-dom"}, false)
+	want := []string{"npm", "install", "react", "react-dom", "--color=always"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Install = %v, want %v", got, want)
+	}
+
+	got = npmPackageManager{}.Install([]string{"typescript"}, true)
+	want = []string{"npm", "install", "-D", "typescript", "--color=always"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Install (dev) = %v, want %v", got, want)
+	}
+}
+
+func TestPnpmAndYarnAndBunInstallArgs(t *testing.T) {
+	cases := []struct {
+		pm   PackageManager
+		dev  bool
+		want []string
+	}{
+		{pnpmPackageManager{}, false, []string{"pnpm", "add", "react"}},
+		{pnpmPackageManager{}, true, []string{"pnpm", "add", "-D", "react"}},
+		{yarnPackageManager{}, false, []string{"yarn", "add", "react"}},
+		{yarnPackageManager{}, true, []string{"yarn", "add", "-D", "react"}},
+		{bunPackageManager{}, false, []string{"bun", "add", "react"}},
+		{bunPackageManager{}, true, []string{"bun", "add", "-d", "react"}},
+	}
+
+	for _, c := range cases {
+		got := c.pm.Install([]string{"react"}, c.dev)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v.Install(dev=%v) = %v, want %v", c.pm.Name(), c.dev, got, c.want)
+		}
+	}
+}
+
+func TestNpmParseAudit(t *testing.T) {
+	pm := npmPackageManager{}
+
+	audit, err := pm.ParseAudit([]byte(`{"metadata":{"vulnerabilities":{"high":2,"critical":1,"total":3}}}`))
+	if err != nil {
+		t.Fatalf("ParseAudit: %v", err)
+	}
+
+	if audit.Metadata.Vulnerabilities.High != 2 || audit.Metadata.Vulnerabilities.Critical != 1 {
+		t.Errorf("audit = %+v, want high=2 critical=1", audit.Metadata.Vulnerabilities)
+	}
+}
+
+func TestYarnParseAuditReadsAuditSummaryLine(t *testing.T) {
+	pm := yarnPackageManager{}
+
+	data := []byte(`{"type":"info","data":"ignore me"}
+{"type":"auditAdvisory","data":{}}
+{"type":"auditSummary","data":{"vulnerabilities":{"info":1,"low":2,"moderate":3,"high":4,"critical":5}}}
+`)
+
+	audit, err := pm.ParseAudit(data)
+	if err != nil {
+		t.Fatalf("ParseAudit: %v", err)
+	}
+
+	v := audit.Metadata.Vulnerabilities
+	if v.Info != 1 || v.Low != 2 || v.Moderate != 3 || v.High != 4 || v.Critical != 5 || v.Total != 15 {
+		t.Errorf("vulnerabilities = %+v, want info=1 low=2 moderate=3 high=4 critical=5 total=15", v)
+	}
+}
+
+func TestYarnParseAuditNoSummaryLine(t *testing.T) {
+	pm := yarnPackageManager{}
+
+	audit, err := pm.ParseAudit([]byte(`{"type":"info","data":"nothing relevant"}` + "\n"))
+	if err != nil {
+		t.Fatalf("ParseAudit: %v", err)
+	}
+
+	if audit.Metadata.Vulnerabilities.Total != 0 {
+		t.Errorf("vulnerabilities.Total = %v, want 0 with no auditSummary line", audit.Metadata.Vulnerabilities.Total)
+	}
+}
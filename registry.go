@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const npmRegistryURL = "https://registry.npmjs.com"
+
+var (
+	registryClient     = &http.Client{Timeout: 5 * time.Second}
+	ErrPackageNotFound = errors.New("package not found on npm registry")
+)
+
+// Version mirrors the subset of an npm registry version entry npminit needs
+// to resolve install targets and walk the dependency graph.
+type Version struct {
+	Name             string            `json:"name"`
+	Version          string            `json:"version"`
+	Dependencies     map[string]string `json:"dependencies"`
+	PeerDependencies map[string]string `json:"peerDependencies"`
+}
+
+// PackageResponse mirrors the shape returned by GET /<name> on the npm
+// registry (see gpm's equivalent struct).
+type PackageResponse struct {
+	Name     string             `json:"name"`
+	DistTags map[string]string  `json:"dist-tags"`
+	Versions map[string]Version `json:"versions"`
+}
+
+func fetchPackageInfo(name string) (PackageResponse, error) {
+	var pkg PackageResponse
+
+	resp, err := registryClient.Get(fmt.Sprintf("%v/%v", npmRegistryURL, name))
+	if err != nil {
+		return pkg, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return pkg, ErrPackageNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return pkg, fmt.Errorf("npm registry returned status %v for %q", resp.StatusCode, name)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&pkg); err != nil {
+		return pkg, err
+	}
+
+	return pkg, nil
+}
+
+// resolveVersion resolves want (a dist-tag, exact version, or empty string for
+// "latest") against pkg, returning the exact version to pin in package.json.
+func resolveVersion(pkg PackageResponse, want string) (string, error) {
+	if want == "" {
+		want = "latest"
+	}
+
+	if tag, ok := pkg.DistTags[want]; ok {
+		return tag, nil
+	}
+
+	if _, ok := pkg.Versions[want]; ok {
+		return want, nil
+	}
+
+	return "", fmt.Errorf("no version %q found for %q", want, pkg.Name)
+}
+
+// projectNameTaken reports whether name is already registered on npm.
+func projectNameTaken(name string) (bool, error) {
+	_, err := fetchPackageInfo(name)
+
+	if errors.Is(err, ErrPackageNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/virtuallyunknown/npminit/resolver"
+)
+
+// resolveDependencies walks the selected dependencies' transitive
+// dependencies/peerDependencies and reports any version conflicts before
+// Page3 starts installing. It resolves over the selected dependencies plus
+// the extra dev dependencies they imply (extraDependenciesFor), the same
+// final set scripted mode checks, so neither path can install an extra the
+// other would have flagged.
+func resolveDependencies(m *Model) tea.Msg {
+	var names []string
+
+	for _, dep := range m.dependencies {
+		if dep.selected {
+			names = append(names, dep.name)
+		}
+	}
+
+	for _, dep := range extraDependenciesFor(m.dependencies) {
+		names = append(names, dep.name)
+	}
+
+	res := resolver.New(resolverFetch)
+
+	conflicts, err := res.Resolve(names, m.overrides)
+	if err != nil {
+		// a registry/network hiccup shouldn't block setup; proceed without
+		// conflict detection rather than failing the whole run
+		return ResolveMsg{}
+	}
+
+	return ResolveMsg{conflicts: conflicts}
+}
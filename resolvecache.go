@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/virtuallyunknown/npminit/resolver"
+)
+
+// npminitCacheDir returns ~/.cache/npminit, creating it if necessary.
+func npminitCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(home, ".cache", "npminit")
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// cacheEntryGlob finds the single cached entry for name, named
+// "<name>@<etag>.json" (with "/" in name replaced by "_" and the etag
+// base64url-encoded so it round-trips through a filename unchanged).
+func cacheEntryGlob(dir, name string) (etag string, path string) {
+	safeName := strings.ReplaceAll(name, "/", "_")
+
+	matches, err := filepath.Glob(filepath.Join(dir, safeName+"@*.json"))
+	if err != nil || len(matches) == 0 {
+		return "", ""
+	}
+
+	base := filepath.Base(matches[0])
+	encodedETag := strings.TrimSuffix(strings.TrimPrefix(base, safeName+"@"), ".json")
+
+	decoded, err := base64.URLEncoding.DecodeString(encodedETag)
+	if err != nil {
+		return "", ""
+	}
+
+	return string(decoded), matches[0]
+}
+
+func writeCacheEntry(dir, name, etag string, data []byte) {
+	safeName := strings.ReplaceAll(name, "/", "_")
+	encodedETag := base64.URLEncoding.EncodeToString([]byte(etag))
+
+	// a new etag replaces the old entry rather than accumulating alongside
+	// it, since cacheEntryGlob only ever reads one match for name.
+	if stale, err := filepath.Glob(filepath.Join(dir, safeName+"@*.json")); err == nil {
+		for _, path := range stale {
+			_ = os.Remove(path)
+		}
+	}
+
+	// best-effort: a cache write failure shouldn't fail the resolve
+	_ = os.WriteFile(filepath.Join(dir, fmt.Sprintf("%v@%v.json", safeName, encodedETag)), data, 0644)
+}
+
+// fetchPackageInfoCached fetches name's registry metadata, reusing a disk
+// cache under ~/.cache/npminit keyed by name@etag so unchanged packages
+// aren't re-fetched on every resolve.
+func fetchPackageInfoCached(name string) (PackageResponse, error) {
+	var pkg PackageResponse
+
+	dir, err := npminitCacheDir()
+	if err != nil {
+		return fetchPackageInfo(name)
+	}
+
+	cachedETag, cachedPath := cacheEntryGlob(dir, name)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/%v", npmRegistryURL, name), nil)
+	if err != nil {
+		return pkg, err
+	}
+
+	if cachedETag != "" {
+		req.Header.Set("If-None-Match", cachedETag)
+	}
+
+	resp, err := registryClient.Do(req)
+	if err != nil {
+		return pkg, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cachedPath != "" {
+		data, err := os.ReadFile(cachedPath)
+		if err != nil {
+			return pkg, err
+		}
+
+		return pkg, json.Unmarshal(data, &pkg)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return pkg, ErrPackageNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return pkg, fmt.Errorf("npm registry returned status %v for %q", resp.StatusCode, name)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return pkg, err
+	}
+
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return pkg, err
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		writeCacheEntry(dir, name, etag, data)
+	}
+
+	return pkg, nil
+}
+
+// resolverFetch adapts the npm registry's own response shape to the shape
+// resolver.Resolver walks, going through the on-disk cache.
+func resolverFetch(name string) (resolver.PackageResponse, error) {
+	pkg, err := fetchPackageInfoCached(name)
+	if err != nil {
+		return resolver.PackageResponse{}, err
+	}
+
+	releases := make(map[string]resolver.PackageRelease, len(pkg.Versions))
+
+	for version, entry := range pkg.Versions {
+		releases[version] = resolver.PackageRelease{
+			Version:          entry.Version,
+			Dependencies:     entry.Dependencies,
+			PeerDependencies: entry.PeerDependencies,
+		}
+	}
+
+	return resolver.PackageResponse{Name: pkg.Name, Releases: releases}, nil
+}
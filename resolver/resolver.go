@@ -0,0 +1,252 @@
+// Package resolver walks a set of npm packages and their transitive
+// dependencies/peerDependencies, collecting every requester's semver range
+// per package name and flagging packages no single version can satisfy.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// PackageRelease mirrors a single version entry of an npm registry package,
+// as needed to walk its dependency graph.
+type PackageRelease struct {
+	Version          string
+	Dependencies     map[string]string
+	PeerDependencies map[string]string
+}
+
+// PackageResponse mirrors the registry response for a package, keyed by
+// version string.
+type PackageResponse struct {
+	Name     string
+	Releases map[string]PackageRelease
+}
+
+// FetchFunc fetches a package's registry metadata. Callers inject this so
+// the resolver stays free of HTTP and caching concerns.
+type FetchFunc func(name string) (PackageResponse, error)
+
+// Constraint is one requester's semver range on a package.
+type Constraint struct {
+	Requester string
+	Range     string
+}
+
+// Conflict is a package for which no single version satisfies every
+// collected constraint. Owners are the top-level package names (the ones
+// Resolve was called with) that transitively pulled Name in, so a caller can
+// offer to drop one of those instead of Name itself, which is often not
+// something the user selected directly.
+type Conflict struct {
+	Name        string
+	Constraints []Constraint
+	Owners      []string
+}
+
+// Resolver accumulates constraints while walking a dependency graph.
+type Resolver struct {
+	fetch       FetchFunc
+	constraints map[string][]Constraint
+	packages    map[string]PackageResponse
+	visited     map[string]bool
+	overrides   map[string]string
+}
+
+func New(fetch FetchFunc) *Resolver {
+	return &Resolver{
+		fetch:       fetch,
+		constraints: map[string][]Constraint{},
+		packages:    map[string]PackageResponse{},
+		visited:     map[string]bool{},
+	}
+}
+
+// Resolve walks deps (top-level package names) and everything they pull in
+// transitively, returning every package whose collected constraints have no
+// mutually satisfying version. overrides forces the given range for a
+// package name regardless of what its requesters declared, letting a caller
+// resolve a conflict by pinning one side instead of dropping a dependency.
+func (r *Resolver) Resolve(deps []string, overrides map[string]string) ([]Conflict, error) {
+	r.overrides = overrides
+
+	for _, dep := range deps {
+		if err := r.walk(dep, "<root>", "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.conflicts(), nil
+}
+
+func (r *Resolver) walk(name, requester, rangeStr string) error {
+	if override, ok := r.overrides[name]; ok {
+		rangeStr = override
+	}
+
+	r.constraints[name] = append(r.constraints[name], Constraint{Requester: requester, Range: rangeStr})
+
+	visitKey := name + "@" + requester
+	if r.visited[visitKey] {
+		return nil
+	}
+	r.visited[visitKey] = true
+
+	pkg, ok := r.packages[name]
+	if !ok {
+		fetched, err := r.fetch(name)
+		if err != nil {
+			return fmt.Errorf("resolving %v: %w", name, err)
+		}
+
+		pkg = fetched
+		r.packages[name] = pkg
+	}
+
+	version, err := bestVersion(pkg, rangeStr)
+	if err != nil {
+		// no version satisfies this single range yet; conflicts() below
+		// surfaces it once every requester's constraint is collected
+		return nil
+	}
+
+	release, ok := pkg.Releases[version]
+	if !ok {
+		return nil
+	}
+
+	for depName, depRange := range release.Dependencies {
+		if err := r.walk(depName, name, depRange); err != nil {
+			return err
+		}
+	}
+
+	for depName, depRange := range release.PeerDependencies {
+		if err := r.walk(depName, name, depRange); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bestVersion(pkg PackageResponse, rangeStr string) (string, error) {
+	constraint, err := semver.NewConstraint(rangeStr)
+	if err != nil {
+		return "", err
+	}
+
+	var best *semver.Version
+
+	for v := range pkg.Releases {
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		if !constraint.Check(version) {
+			continue
+		}
+
+		if best == nil || version.GreaterThan(best) {
+			best = version
+		}
+	}
+
+	if best == nil {
+		return "", fmt.Errorf("no version of %v satisfies %v", pkg.Name, rangeStr)
+	}
+
+	return best.String(), nil
+}
+
+// conflicts returns every package whose collected constraints have no
+// version satisfying all of them at once.
+func (r *Resolver) conflicts() []Conflict {
+	var conflicts []Conflict
+
+	for name, constraints := range r.constraints {
+		pkg, ok := r.packages[name]
+		if !ok || anyVersionSatisfies(pkg, constraints) {
+			continue
+		}
+
+		conflicts = append(conflicts, Conflict{Name: name, Constraints: constraints, Owners: r.owners(name)})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+
+	return conflicts
+}
+
+// owners traces name's requesters back up to whichever top-level packages
+// (the ones passed to Resolve) pulled it in, directly or transitively.
+func (r *Resolver) owners(name string) []string {
+	seen := map[string]bool{}
+	found := map[string]bool{}
+
+	var visit func(string)
+	visit = func(n string) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+
+		for _, c := range r.constraints[n] {
+			if c.Requester == "<root>" {
+				found[n] = true
+				continue
+			}
+
+			visit(c.Requester)
+		}
+	}
+
+	visit(name)
+
+	owners := make([]string, 0, len(found))
+	for owner := range found {
+		owners = append(owners, owner)
+	}
+
+	sort.Strings(owners)
+
+	return owners
+}
+
+func anyVersionSatisfies(pkg PackageResponse, constraints []Constraint) bool {
+	parsed := make([]*semver.Constraints, 0, len(constraints))
+
+	for _, c := range constraints {
+		constraint, err := semver.NewConstraint(c.Range)
+		if err != nil {
+			continue
+		}
+
+		parsed = append(parsed, constraint)
+	}
+
+	for v := range pkg.Releases {
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+
+		satisfiesAll := true
+
+		for _, constraint := range parsed {
+			if !constraint.Check(version) {
+				satisfiesAll = false
+				break
+			}
+		}
+
+		if satisfiesAll {
+			return true
+		}
+	}
+
+	return false
+}
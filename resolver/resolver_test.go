@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func fakeFetch(packages map[string]PackageResponse) FetchFunc {
+	return func(name string) (PackageResponse, error) {
+		return packages[name], nil
+	}
+}
+
+func release(deps map[string]string) PackageRelease {
+	return PackageRelease{Dependencies: deps}
+}
+
+func TestResolveNoConflict(t *testing.T) {
+	packages := map[string]PackageResponse{
+		"a": {Name: "a", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^1.0.0"}),
+		}},
+		"b": {Name: "b", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^1.2.0"}),
+		}},
+		"shared": {Name: "shared", Releases: map[string]PackageRelease{
+			"1.0.0": release(nil),
+			"1.3.0": release(nil),
+		}},
+	}
+
+	r := New(fakeFetch(packages))
+
+	conflicts, err := r.Resolve([]string{"a", "b"}, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+}
+
+func TestResolveTransitiveConflict(t *testing.T) {
+	packages := map[string]PackageResponse{
+		"a": {Name: "a", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^1.0.0"}),
+		}},
+		"b": {Name: "b", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^2.0.0"}),
+		}},
+		"shared": {Name: "shared", Releases: map[string]PackageRelease{
+			"1.0.0": release(nil),
+			"2.0.0": release(nil),
+		}},
+	}
+
+	r := New(fakeFetch(packages))
+
+	conflicts, err := r.Resolve([]string{"a", "b"}, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Name != "shared" {
+		t.Fatalf("expected a single conflict on shared, got %+v", conflicts)
+	}
+
+	owners := conflicts[0].Owners
+	sort.Strings(owners)
+
+	if !reflect.DeepEqual(owners, []string{"a", "b"}) {
+		t.Fatalf("expected owners [a b], got %v", owners)
+	}
+}
+
+func TestResolveOverrideClearsConflict(t *testing.T) {
+	packages := map[string]PackageResponse{
+		"a": {Name: "a", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^1.0.0"}),
+		}},
+		"b": {Name: "b", Releases: map[string]PackageRelease{
+			"1.0.0": release(map[string]string{"shared": "^2.0.0"}),
+		}},
+		"shared": {Name: "shared", Releases: map[string]PackageRelease{
+			"1.0.0": release(nil),
+			"2.0.0": release(nil),
+		}},
+	}
+
+	r := New(fakeFetch(packages))
+
+	conflicts, err := r.Resolve([]string{"a", "b"}, map[string]string{"shared": "^2.0.0"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	if len(conflicts) != 0 {
+		t.Fatalf("expected the override to clear the conflict, got %+v", conflicts)
+	}
+}
@@ -2,31 +2,17 @@ package main
 
 import (
 	"bytes"
-	_ "embed"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type EmbeddedFile []byte
-
-var (
-	//go:embed static/tailwind.config.js
-	TailwindConfigJs EmbeddedFile
-	//go:embed static/eslintrc.cjs
-	EslintConfigJs EmbeddedFile
-	//go:embed static/esbuild.js
-	EsbuildJs EmbeddedFile
-	//go:embed static/index.ts
-	IndexTs EmbeddedFile
-)
-
 type packageJSON struct {
 	Name        string `json:"name"`
 	Author      string `json:"author"`
@@ -36,26 +22,14 @@ type packageJSON struct {
 	Type        string `json:"type"`
 }
 
+// tsconfigJSON's CompilerOptions is a plain map rather than a fixed struct so
+// a Template's TsconfigOverrides can add or replace any compiler option
+// without tsconfigJSON needing a field for every option a template might
+// want (see defaultCompilerOptions and generateTsconfigJSON).
 type tsconfigJSON struct {
-	CompilerOptions struct {
-		Module                           string `json:"module,omitempty"`
-		ModuleResolution                 string `json:"moduleResolution,omitempty"`
-		Target                           string `json:"target,omitempty"`
-		ForceConsistentCasingInFileNames bool   `json:"forceConsistentCasingInFileNames,omitempty"`
-		AllowUnreachableCode             bool   `json:"allowUnreachableCode,omitempty"`
-		NoErrorTruncation                bool   `json:"noErrorTruncation,omitempty"`
-		EsModuleInterop                  bool   `json:"esModuleInterop,omitempty"`
-		IsolatedModules                  bool   `json:"isolatedModules,omitempty"`
-		ResolveJSONModule                bool   `json:"resolveJsonModule,omitempty"`
-		SkipLibCheck                     bool   `json:"skipLibCheck,omitempty"`
-		Jsx                              string `json:"jsx,omitempty"`
-		Strict                           bool   `json:"strict,omitempty"`
-		NoEmit                           bool   `json:"noEmit,omitempty"`
-		RootDir                          string `json:"rootDir,omitempty"`
-		OutDir                           string `json:"outDir,omitempty"`
-	} `json:"compilerOptions"`
-	Include []string `json:"include"`
-	Exclude []string `json:"exclude"`
+	CompilerOptions map[string]any `json:"compilerOptions"`
+	Include         []string       `json:"include"`
+	Exclude         []string       `json:"exclude"`
 }
 
 type npmAuditJSON struct {
@@ -79,41 +53,47 @@ type npmAuditJSON struct {
 	} `json:"metadata"`
 }
 
-func generateTsconfigJSON() tsconfigJSON {
+// defaultCompilerOptions are applied to every project before a template's
+// TsconfigOverrides layer on top.
+func defaultCompilerOptions() map[string]any {
+	return map[string]any{
+		"module":                           "NodeNext",
+		"moduleResolution":                 "NodeNext",
+		"target":                           "ESNext",
+		"forceConsistentCasingInFileNames": true,
+		"esModuleInterop":                  true,
+		"strict":                           true,
+	}
+}
+
+// generateTsconfigJSON builds the tsconfig.json content for tmpl, merging
+// its TsconfigOverrides over defaultCompilerOptions so e.g. a template that
+// needs "jsx" or a different "outDir" can declare just that option. tmpl may
+// be nil, in which case only the defaults apply.
+func generateTsconfigJSON(tmpl *Template) tsconfigJSON {
+	options := defaultCompilerOptions()
+
+	if tmpl != nil {
+		for key, value := range tmpl.TsconfigOverrides {
+			options[key] = value
+		}
+	}
+
 	return tsconfigJSON{
-		CompilerOptions: struct {
-			Module                           string `json:"module,omitempty"`
-			ModuleResolution                 string `json:"moduleResolution,omitempty"`
-			Target                           string `json:"target,omitempty"`
-			ForceConsistentCasingInFileNames bool   `json:"forceConsistentCasingInFileNames,omitempty"`
-			AllowUnreachableCode             bool   `json:"allowUnreachableCode,omitempty"`
-			NoErrorTruncation                bool   `json:"noErrorTruncation,omitempty"`
-			EsModuleInterop                  bool   `json:"esModuleInterop,omitempty"`
-			IsolatedModules                  bool   `json:"isolatedModules,omitempty"`
-			ResolveJSONModule                bool   `json:"resolveJsonModule,omitempty"`
-			SkipLibCheck                     bool   `json:"skipLibCheck,omitempty"`
-			Jsx                              string `json:"jsx,omitempty"`
-			Strict                           bool   `json:"strict,omitempty"`
-			NoEmit                           bool   `json:"noEmit,omitempty"`
-			RootDir                          string `json:"rootDir,omitempty"`
-			OutDir                           string `json:"outDir,omitempty"`
-		}{
-			Module:                           "NodeNext",
-			ModuleResolution:                 "NodeNext",
-			Target:                           "ESNext",
-			ForceConsistentCasingInFileNames: true,
-			EsModuleInterop:                  true,
-			Strict:                           true,
-		},
-		Include: []string{"src/**/*.*"},
-		Exclude: []string{"**/node_modules", "**/.*/"},
+		CompilerOptions: options,
+		Include:         []string{"src/**/*.*"},
+		Exclude:         []string{"**/node_modules", "**/.*/"},
 	}
 }
 
-func generatePackageJSON(projectName string) packageJSON {
+func generatePackageJSON(projectName string, version string) packageJSON {
+	if version == "" {
+		version = "1.0.0"
+	}
+
 	return packageJSON{
 		Name:    projectName,
-		Version: "1.0.0",
+		Version: version,
 		Type:    "module",
 	}
 }
@@ -138,40 +118,37 @@ func execOutput(args []string, dir string) (string, ExecError) {
 	return stdout.String(), ExecError{}
 }
 
-func copyStaticFile(m *Model, staticFile EmbeddedFile, fileName string) error {
-	if err := os.WriteFile(path.Join(m.projectPath, fileName), staticFile, 0644); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func getProjectPath(projectName string) (string, error) {
-	// get the current working directory
+// getProjectPath resolves projectName to an absolute path and prepares it
+// for setup. A directory that already exists is only allowed when it holds
+// a valid journal from a previous run, in which case its journal is
+// returned so setup can resume instead of redoing finished steps.
+func getProjectPath(projectName string) (string, *Journal, error) {
 	cwd, err := os.Getwd()
-
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	// set project path
 	projectPath := path.Join(cwd, projectName)
 
-	// check if path exists
-	dir, err := os.Open(projectPath)
+	if info, statErr := os.Stat(projectPath); statErr == nil && info.IsDir() {
+		if journal, journalErr := loadJournal(projectPath); journalErr == nil {
+			return projectPath, journal, nil
+		}
 
-	if !errors.Is(err, os.ErrNotExist) {
-		return "", fmt.Errorf("Unable to create project at %v directory already exists.", projectPath)
+		return "", nil, fmt.Errorf("Unable to create project at %v directory already exists.", projectPath)
 	}
 
-	defer dir.Close()
-
-	// create project path
 	if err := os.MkdirAll(path.Join(projectPath, "src"), 0755); err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	journal := &Journal{ProjectPath: projectPath}
+
+	if err := journal.recordDir("src"); err != nil {
+		return "", nil, err
 	}
 
-	return projectPath, nil
+	return projectPath, journal, nil
 }
 
 func writeJson[T packageJSON | tsconfigJSON](dir string, fileName string, data T) error {
@@ -188,105 +165,186 @@ func writeJson[T packageJSON | tsconfigJSON](dir string, fileName string, data T
 	return nil
 }
 
-func installDependency(m *Model, i int) tea.Msg {
-	args := []string{"npm", "install"}
+// resolveInstallTargets looks up the exact "name@version" to install for
+// every selected, not-yet-installed dependency, using a bounded worker pool
+// since these are read-only registry lookups with no shared state to race
+// on. The installs that follow (installBatch) group by devDependency and
+// install each group in one process, since installs do mutate the shared
+// package.json/lockfile.
+func resolveInstallTargets(m *Model) tea.Msg {
+	type resolvedTarget struct {
+		index  int
+		target string
+	}
+
+	jobs := make(chan int)
+	results := make(chan resolvedTarget)
 
-	if m.dependencies[i].devDependency {
-		args = append(args, "-D")
+	concurrency := m.lookupConcurrency
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	args = append(args, m.dependencies[i].name, "--color=always")
+	var wg sync.WaitGroup
 
-	_, err := execOutput(args, m.projectPath)
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
 
-	if err.error != nil {
-		return ErrorMsg{error: err.error}
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				dep := m.dependencies[i]
+				target := dep.name
+
+				if pkg, err := fetchPackageInfo(dep.name); err == nil {
+					if version, err := resolveVersion(pkg, dep.version); err == nil {
+						target = fmt.Sprintf("%v@%v", dep.name, version)
+					}
+				}
+
+				results <- resolvedTarget{i, target}
+			}
+		}()
 	}
 
-	return OnInstalledMsg{
-		index: i,
+	go func() {
+		for i := range m.dependencies {
+			if m.dependencies[i].selected && !m.dependencies[i].installed {
+				jobs <- i
+			}
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	targets := make(map[int]string)
+	for result := range results {
+		targets[result.index] = result.target
 	}
+
+	return TargetsResolvedMsg{targets: targets}
 }
 
-func runAudit(m *Model) tea.Msg {
-	var audit npmAuditJSON
+// installBatch installs every dependency in indices (all selected,
+// not-yet-installed, and sharing the same devDependency-ness) through a
+// single package manager invocation, so the group installs together while
+// package.json/the lockfile still only ever see one writer at a time.
+func installBatch(m *Model, indices []int, dev bool) tea.Msg {
+	targets := make([]string, 0, len(indices))
+
+	for _, i := range indices {
+		dep := m.dependencies[i]
+
+		target := dep.target
+		if target == "" {
+			target = dep.name
+		}
+
+		targets = append(targets, target)
+	}
+
+	args := m.packageManager.Install(targets, dev)
+
+	_, execErr := execOutput(args, m.projectPath)
+
+	if execErr.error != nil {
+		return ErrorMsg{error: execErr.error}
+	}
+
+	if m.journal != nil {
+		for _, i := range indices {
+			if err := m.journal.recordDependency(m.dependencies[i].name); err != nil {
+				return ErrorMsg{error: err}
+			}
+		}
+	}
 
-	args := []string{"npm", "audit", "--json"}
+	return OnBatchInstalledMsg{
+		indices: indices,
+	}
+}
+
+func runAudit(m *Model) tea.Msg {
+	args := m.packageManager.Audit()
 	data, err := execOutput(args, m.projectPath)
 
-	// if there are vulenrabilities npm will return as error from stdout
+	// if there are vulnerabilities the package manager exits non-zero but
+	// still writes the report to stdout
 	if err.error != nil {
-		jsonErr := json.Unmarshal([]byte(err.stdout), &audit)
+		audit, parseErr := m.packageManager.ParseAudit([]byte(err.stdout))
 
-		if jsonErr != nil {
+		if parseErr != nil {
 			return ErrorMsg{error: err.error}
 		} else {
 			return AuditMsg{audit: audit}
 		}
 	}
 
-	jsonErr := json.Unmarshal([]byte(data), &audit)
+	audit, parseErr := m.packageManager.ParseAudit([]byte(data))
 
-	if jsonErr != nil {
-		return ErrorMsg{error: jsonErr}
+	if parseErr != nil {
+		return ErrorMsg{error: parseErr}
 	}
 
 	return AuditMsg{audit: audit}
 }
 
 func setupProject(m *Model) tea.Msg {
-	packageJSON, tsconfigJSON := generatePackageJSON(m.textinput.Value()), generateTsconfigJSON()
-	projectPath, err := getProjectPath(m.textinput.Value())
-
-	if err != nil {
+	if err := validateProjectName(m.projectName); err != nil {
 		return ErrorMsg{error: err}
 	}
 
-	err = writeJson(projectPath, "package.json", packageJSON)
+	projectPath, journal, err := getProjectPath(m.projectName)
 	if err != nil {
 		return ErrorMsg{error: err}
 	}
 
-	err = writeJson(projectPath, "tsconfig.json", tsconfigJSON)
-	if err != nil {
-		return ErrorMsg{error: err}
-	}
+	if !journal.hasFile("package.json") {
+		if err := writeJson(projectPath, "package.json", generatePackageJSON(m.projectName, m.projectVersion)); err != nil {
+			return ErrorMsg{error: err}
+		}
 
-	err = copyStaticFile(m, EslintConfigJs, "eslintrc.cjs")
-	if err != nil {
-		return ErrorMsg{error: err}
+		if err := journal.recordFile("package.json"); err != nil {
+			return ErrorMsg{error: err}
+		}
 	}
 
-	err = copyStaticFile(m, EslintConfigJs, "esbuild.js")
-	if err != nil {
-		return ErrorMsg{error: err}
+	if !journal.hasFile("tsconfig.json") {
+		if err := writeJson(projectPath, "tsconfig.json", generateTsconfigJSON(m.template)); err != nil {
+			return ErrorMsg{error: err}
+		}
+
+		if err := journal.recordFile("tsconfig.json"); err != nil {
+			return ErrorMsg{error: err}
+		}
 	}
 
-	return SetupMessage{projectPath: projectPath}
+	// Template files aren't copied here: some (esbuild.js, tailwind.config.js)
+	// only make sense if the matching Dependency is still selected, and that
+	// isn't decided until Page2. See extraDependencies, which runs once that
+	// selection is known.
+
+	taken, _ := projectNameTaken(m.projectName)
+
+	return SetupMessage{projectPath: projectPath, taken: taken, journal: journal}
 }
 
-func extraDependencies(m *Model) tea.Msg {
+// extraDependenciesFor returns the extra dev dependencies selected implies
+// (e.g. react pulls in its type defs and lint plugins), so the interactive
+// and scripted paths derive the same package.json from the same template.
+func extraDependenciesFor(selected []Dependency) []Dependency {
 	var deps []Dependency
 
-	for _, dep := range m.dependencies {
+	for _, dep := range selected {
 		if !dep.selected {
 			continue
 		}
 
-		if dep.name == "typescript" {
-			err := copyStaticFile(m, IndexTs, "src/index.ts")
-			if err != nil {
-				return ErrorMsg{error: err}
-			}
-		}
-
-		if dep.name == "esbuild" {
-			err := copyStaticFile(m, EsbuildJs, "esbuild.js")
-			if err != nil {
-				return ErrorMsg{error: err}
-			}
-		}
-
 		if dep.name == "react" {
 			deps = append(deps,
 				Dependency{name: "@types/react", selected: true, devDependency: true},
@@ -303,15 +361,33 @@ func extraDependencies(m *Model) tea.Msg {
 			)
 		}
 
-		if dep.name == "tailwindcss" {
-			err := copyStaticFile(m, TailwindConfigJs, "tailwind.config.js")
-			if err != nil {
+	}
+
+	return deps
+}
+
+// extraDependencies copies the template's files gated on what's still
+// selected after Page2 (see selectedTemplateFiles), then resolves the extra
+// dev dependencies that selection implies.
+func extraDependencies(m *Model) tea.Msg {
+	if !m.journal.FilesCopied {
+		written, err := copyTemplateFiles(selectedTemplateFiles(m.template, m.dependencies), m.projectPath)
+		if err != nil {
+			return ErrorMsg{error: err}
+		}
+
+		for _, file := range written {
+			if err := m.journal.recordFile(file); err != nil {
 				return ErrorMsg{error: err}
 			}
 		}
+
+		if err := m.journal.recordFilesCopied(); err != nil {
+			return ErrorMsg{error: err}
+		}
 	}
 
-	return ExtraDepsMessage{dependencies: deps}
+	return ExtraDepsMessage{dependencies: extraDependenciesFor(m.dependencies)}
 }
 
 func severityStatus(audit *npmAuditJSON) string {
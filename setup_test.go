@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestGenerateTsconfigJSONAppliesDefaultsWithNilTemplate(t *testing.T) {
+	tsconfig := generateTsconfigJSON(nil)
+
+	if tsconfig.CompilerOptions["module"] != "NodeNext" {
+		t.Errorf("CompilerOptions[module] = %v, want NodeNext", tsconfig.CompilerOptions["module"])
+	}
+
+	if tsconfig.CompilerOptions["strict"] != true {
+		t.Errorf("CompilerOptions[strict] = %v, want true", tsconfig.CompilerOptions["strict"])
+	}
+}
+
+func TestGenerateTsconfigJSONTemplateOverridesDefaults(t *testing.T) {
+	tmpl := &Template{
+		TsconfigOverrides: map[string]any{
+			"jsx":    "react-jsx",
+			"strict": false,
+		},
+	}
+
+	tsconfig := generateTsconfigJSON(tmpl)
+
+	if tsconfig.CompilerOptions["jsx"] != "react-jsx" {
+		t.Errorf("CompilerOptions[jsx] = %v, want react-jsx", tsconfig.CompilerOptions["jsx"])
+	}
+
+	if tsconfig.CompilerOptions["strict"] != false {
+		t.Errorf("CompilerOptions[strict] = %v, want the template's override (false)", tsconfig.CompilerOptions["strict"])
+	}
+
+	// Defaults not mentioned in TsconfigOverrides still apply.
+	if tsconfig.CompilerOptions["module"] != "NodeNext" {
+		t.Errorf("CompilerOptions[module] = %v, want the untouched default NodeNext", tsconfig.CompilerOptions["module"])
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+)
+
+//go:embed templates
+var builtinTemplatesFS embed.FS
+
+const manifestName = "template"
+
+// TemplateDependency is a dependency a Template wants pre-selected on Page2.
+type TemplateDependency struct {
+	Name string `toml:"name" json:"name"`
+	Dev  bool   `toml:"dev" json:"dev"`
+}
+
+// TemplateFile is a file the template copies into the new project, read
+// relative to the template directory's "files" subdirectory. Requires, if
+// set, names a Dependency this file's contents assume is installed (e.g.
+// esbuild.js requires "esbuild"); selectedTemplateFiles drops the file if
+// that dependency ends up deselected on Page2.
+type TemplateFile struct {
+	Source   string `toml:"source" json:"source"`
+	Dest     string `toml:"dest" json:"dest"`
+	Requires string `toml:"requires" json:"requires"`
+	content  []byte
+}
+
+// Template is a scaffolding preset: a dependency set plus files to copy into
+// the freshly created project. Built-ins ship embedded under templates/;
+// users can add their own under ~/.config/npminit/templates/<key>/.
+type Template struct {
+	Key               string               `toml:"key" json:"key"`
+	Name              string               `toml:"name" json:"name"`
+	Description       string               `toml:"description" json:"description"`
+	Dependencies      []TemplateDependency `toml:"dependencies" json:"dependencies"`
+	Files             []TemplateFile       `toml:"files" json:"files"`
+	TsconfigOverrides map[string]any       `toml:"tsconfig_overrides" json:"tsconfigOverrides"`
+}
+
+// loadTemplates returns the built-in templates plus any found under the
+// user's config directory, sorted by key.
+func loadTemplates() ([]Template, error) {
+	templates, err := loadTemplatesFromFS(builtinTemplatesFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		userDir := filepath.Join(home, ".config", "npminit", "templates")
+
+		if user, err := loadTemplatesFromDir(userDir); err == nil {
+			templates = append(templates, user...)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Key < templates[j].Key })
+
+	return templates, nil
+}
+
+func loadTemplatesFromDir(dir string) ([]Template, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%v is not a directory", dir)
+	}
+
+	return loadTemplatesFromFS(os.DirFS(dir), ".")
+}
+
+func loadTemplatesFromFS(fsys fs.FS, root string) ([]Template, error) {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []Template
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		dir := path.Join(root, entry.Name())
+
+		tmpl, err := readTemplateManifest(fsys, dir, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		for i, file := range tmpl.Files {
+			data, err := fs.ReadFile(fsys, path.Join(dir, "files", file.Source))
+			if err != nil {
+				return nil, fmt.Errorf("template %v: %w", tmpl.Key, err)
+			}
+
+			tmpl.Files[i].content = data
+		}
+
+		templates = append(templates, tmpl)
+	}
+
+	return templates, nil
+}
+
+func readTemplateManifest(fsys fs.FS, dir string, key string) (Template, error) {
+	var tmpl Template
+
+	if data, err := fs.ReadFile(fsys, path.Join(dir, manifestName+".toml")); err == nil {
+		if _, err := toml.Decode(string(data), &tmpl); err != nil {
+			return tmpl, err
+		}
+	} else if data, err := fs.ReadFile(fsys, path.Join(dir, manifestName+".json")); err == nil {
+		if err := json.Unmarshal(data, &tmpl); err != nil {
+			return tmpl, err
+		}
+	} else {
+		return tmpl, fmt.Errorf("no %v.toml or %v.json in %v", manifestName, manifestName, dir)
+	}
+
+	if tmpl.Key == "" {
+		tmpl.Key = key
+	}
+
+	return tmpl, nil
+}
+
+// templateDependencies converts a Template's declared dependencies into
+// pre-selected Dependency rows for Page2.
+func templateDependencies(tmpl Template) []Dependency {
+	dependencies := make([]Dependency, 0, len(tmpl.Dependencies))
+
+	for _, dep := range tmpl.Dependencies {
+		dependencies = append(dependencies, Dependency{
+			name:          dep.Name,
+			selected:      true,
+			devDependency: dep.Dev,
+		})
+	}
+
+	return dependencies
+}
+
+// selectedTemplateFiles returns a copy of tmpl with Files filtered down to
+// those whose Requires is either unset or still a selected Dependency in
+// deps, so a file like esbuild.js never lands in a project that deselected
+// esbuild on Page2.
+func selectedTemplateFiles(tmpl *Template, deps []Dependency) *Template {
+	if tmpl == nil {
+		return nil
+	}
+
+	selected := make(map[string]bool, len(deps))
+	for _, dep := range deps {
+		if dep.selected {
+			selected[dep.name] = true
+		}
+	}
+
+	filtered := *tmpl
+	filtered.Files = nil
+
+	for _, file := range tmpl.Files {
+		if file.Requires == "" || selected[file.Requires] {
+			filtered.Files = append(filtered.Files, file)
+		}
+	}
+
+	return &filtered
+}
+
+// copyTemplateFiles writes every file tmpl declares into projectPath,
+// creating any intermediate directories it needs, and returns each file's
+// path relative to projectPath so the caller can journal them.
+func copyTemplateFiles(tmpl *Template, projectPath string) ([]string, error) {
+	if tmpl == nil {
+		return nil, nil
+	}
+
+	written := make([]string, 0, len(tmpl.Files))
+
+	for _, file := range tmpl.Files {
+		dest := path.Join(projectPath, file.Dest)
+
+		if err := os.MkdirAll(path.Dir(dest), 0755); err != nil {
+			return written, err
+		}
+
+		if err := os.WriteFile(dest, file.content, 0644); err != nil {
+			return written, err
+		}
+
+		written = append(written, file.Dest)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadTemplatesFromFSReadsTomlAndJson(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/toml-one/template.toml": {Data: []byte(`
+key = "toml-one"
+name = "TOML One"
+
+[[dependencies]]
+name = "react"
+`)},
+		"templates/json-two/template.json": {Data: []byte(`{"name": "JSON Two", "dependencies": [{"name": "kysely", "dev": true}]}`)},
+	}
+
+	templates, err := loadTemplatesFromFS(fsys, "templates")
+	if err != nil {
+		t.Fatalf("loadTemplatesFromFS: %v", err)
+	}
+
+	if len(templates) != 2 {
+		t.Fatalf("got %d templates, want 2", len(templates))
+	}
+
+	byKey := map[string]Template{}
+	for _, tmpl := range templates {
+		byKey[tmpl.Key] = tmpl
+	}
+
+	tomlTmpl, ok := byKey["toml-one"]
+	if !ok || tomlTmpl.Name != "TOML One" || len(tomlTmpl.Dependencies) != 1 || tomlTmpl.Dependencies[0].Name != "react" {
+		t.Errorf("toml-one = %+v, want name/dependencies parsed from template.toml", tomlTmpl)
+	}
+
+	// template.json has no "key" field, so readTemplateManifest falls back
+	// to the directory name.
+	jsonTmpl, ok := byKey["json-two"]
+	if !ok || jsonTmpl.Name != "JSON Two" || len(jsonTmpl.Dependencies) != 1 || !jsonTmpl.Dependencies[0].Dev {
+		t.Errorf("json-two = %+v, want name/dependencies parsed from template.json with key defaulted", jsonTmpl)
+	}
+}
+
+func TestLoadTemplatesFromFSSkipsDirsWithoutManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/no-manifest/files/index.html": {Data: []byte("<html></html>")},
+	}
+
+	templates, err := loadTemplatesFromFS(fsys, "templates")
+	if err != nil {
+		t.Fatalf("loadTemplatesFromFS: %v", err)
+	}
+
+	if len(templates) != 0 {
+		t.Fatalf("got %d templates, want 0 for a dir with no template.toml/json", len(templates))
+	}
+}
+
+func TestLoadTemplatesFromFSReadsDeclaredFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/with-files/template.toml": {Data: []byte(`
+key = "with-files"
+
+[[files]]
+source = "index.html"
+dest = "index.html"
+`)},
+		"templates/with-files/files/index.html": {Data: []byte("<html>hi</html>")},
+	}
+
+	templates, err := loadTemplatesFromFS(fsys, "templates")
+	if err != nil {
+		t.Fatalf("loadTemplatesFromFS: %v", err)
+	}
+
+	if len(templates) != 1 || len(templates[0].Files) != 1 {
+		t.Fatalf("got %+v, want a single template with a single file", templates)
+	}
+
+	if string(templates[0].Files[0].content) != "<html>hi</html>" {
+		t.Errorf("Files[0].content = %q, want the contents of files/index.html", templates[0].Files[0].content)
+	}
+}
+
+func TestCopyTemplateFilesWritesUnderProjectPath(t *testing.T) {
+	tmpl := &Template{
+		Key: "test",
+		Files: []TemplateFile{
+			{Dest: "src/index.ts", content: []byte("export {}")},
+			{Dest: "README.md", content: []byte("# test")},
+		},
+	}
+
+	dir := t.TempDir()
+
+	written, err := copyTemplateFiles(tmpl, dir)
+	if err != nil {
+		t.Fatalf("copyTemplateFiles: %v", err)
+	}
+
+	if len(written) != 2 {
+		t.Fatalf("got %d written files, want 2", len(written))
+	}
+
+	for _, file := range tmpl.Files {
+		data, err := os.ReadFile(path.Join(dir, file.Dest))
+		if err != nil {
+			t.Fatalf("reading %v: %v", file.Dest, err)
+		}
+
+		if string(data) != string(file.content) {
+			t.Errorf("%v content = %q, want %q", file.Dest, data, file.content)
+		}
+	}
+}
+
+func TestCopyTemplateFilesNilTemplate(t *testing.T) {
+	written, err := copyTemplateFiles(nil, t.TempDir())
+	if err != nil || written != nil {
+		t.Fatalf("copyTemplateFiles(nil, ...) = (%v, %v), want (nil, nil)", written, err)
+	}
+}
+
+func TestSelectedTemplateFilesDropsUnselectedRequires(t *testing.T) {
+	tmpl := &Template{
+		Files: []TemplateFile{
+			{Dest: "esbuild.js", Requires: "esbuild"},
+			{Dest: "tailwind.config.js", Requires: "tailwindcss"},
+			{Dest: "src/index.ts"},
+		},
+	}
+
+	deps := []Dependency{
+		{name: "esbuild", selected: false},
+		{name: "tailwindcss", selected: true},
+	}
+
+	filtered := selectedTemplateFiles(tmpl, deps)
+
+	var dests []string
+	for _, file := range filtered.Files {
+		dests = append(dests, file.Dest)
+	}
+
+	want := []string{"tailwind.config.js", "src/index.ts"}
+	if !reflect.DeepEqual(dests, want) {
+		t.Errorf("selectedTemplateFiles dests = %v, want %v", dests, want)
+	}
+}
+
+func TestSelectedTemplateFilesNilTemplate(t *testing.T) {
+	if selectedTemplateFiles(nil, nil) != nil {
+		t.Error("selectedTemplateFiles(nil, ...) = non-nil, want nil")
+	}
+}
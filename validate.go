@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var projectNamePattern = regexp.MustCompile(`^(@[a-z0-9][a-z0-9._-]*/)?[a-z0-9][a-z0-9._-]*$`)
+
+// validateProjectName enforces npm's package.json "name" rules: lowercase,
+// URL-safe, no leading dot or underscore, an optional "@scope/" prefix, and
+// at most 214 characters.
+func validateProjectName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name cannot be empty")
+	}
+
+	if len(name) > 214 {
+		return fmt.Errorf("project name %q is longer than 214 characters", name)
+	}
+
+	if name != strings.ToLower(name) {
+		return fmt.Errorf("project name %q must be lowercase", name)
+	}
+
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return fmt.Errorf("project name %q cannot start with a dot or underscore", name)
+	}
+
+	if !projectNamePattern.MatchString(name) {
+		return fmt.Errorf("project name %q contains characters that aren't URL-safe", name)
+	}
+
+	return nil
+}
+
+// parseNameVersion splits a Page1 entry like "my-app@1.2.3" into its name and
+// optional version. version is empty when none was given.
+func parseNameVersion(input string) (name string, version string) {
+	if i := strings.LastIndex(input, "@"); i > 0 {
+		return input[:i], input[i+1:]
+	}
+
+	return input, ""
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestValidateProjectName(t *testing.T) {
+	valid := []string{"my-app", "my_app", "my.app", "app2", "@scope/my-app", "@my-org.1/pkg"}
+	for _, name := range valid {
+		if err := validateProjectName(name); err != nil {
+			t.Errorf("validateProjectName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	invalid := []string{"", ".app", "_app", "My-App", "my app", "my/app"}
+	for _, name := range invalid {
+		if err := validateProjectName(name); err == nil {
+			t.Errorf("validateProjectName(%q) = nil, want an error", name)
+		}
+	}
+
+	if err := validateProjectName("@scope"); err == nil {
+		t.Error(`validateProjectName("@scope") = nil, want an error for a missing package segment`)
+	}
+}
+
+func TestValidateProjectNameTooLong(t *testing.T) {
+	name := ""
+	for len(name) <= 214 {
+		name += "a"
+	}
+
+	if err := validateProjectName(name); err == nil {
+		t.Errorf("validateProjectName(%d chars) = nil, want an error", len(name))
+	}
+}
+
+func TestParseNameVersion(t *testing.T) {
+	cases := []struct {
+		input       string
+		wantName    string
+		wantVersion string
+	}{
+		{"my-app", "my-app", ""},
+		{"my-app@1.2.3", "my-app", "1.2.3"},
+		{"@scope/my-app", "@scope/my-app", ""},
+		{"@scope/my-app@1.2.3", "@scope/my-app", "1.2.3"},
+	}
+
+	for _, c := range cases {
+		name, version := parseNameVersion(c.input)
+		if name != c.wantName || version != c.wantVersion {
+			t.Errorf("parseNameVersion(%q) = (%q, %q), want (%q, %q)", c.input, name, version, c.wantName, c.wantVersion)
+		}
+	}
+}